@@ -0,0 +1,313 @@
+package msgsource
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+const (
+	defaultReconnectInterval = 1 * time.Second
+	maxReconnectInterval     = 30 * time.Second
+)
+
+// AMQPConfig configures an AMQPSource.
+type AMQPConfig struct {
+	Address   string
+	QueueName string
+
+	Exchange     string
+	RoutingKey   string
+	ExchangeType string
+
+	Durable    bool
+	Exclusive  bool
+	AutoDelete bool
+
+	PrefetchCount int
+	PrefetchSize  int
+
+	// DeadLetterExchange/DeadLetterQueue, if both set, are declared and
+	// bound, and the consumed queue is declared with
+	// x-dead-letter-exchange pointing at DeadLetterExchange, so a Nack
+	// routes the message there instead of dropping or requeuing it.
+	DeadLetterExchange string
+	DeadLetterQueue    string
+
+	// ReconnectInterval is the initial backoff after a connection or
+	// channel closes; it doubles up to 30s. Defaults to 1s.
+	ReconnectInterval time.Duration
+}
+
+// AMQPSource is a msgsource.MessageSource backed by a RabbitMQ queue,
+// with automatic reconnect and optional dead-letter routing.
+type AMQPSource struct {
+	cfg AMQPConfig
+
+	mu   sync.Mutex
+	conn *amqp.Connection
+	ch   *amqp.Channel
+	q    amqp.Queue
+
+	stopped        chan struct{}
+	reconnectCount uint64
+}
+
+// NewAMQPSource creates an AMQPSource from cfg. Dialing happens in Start.
+func NewAMQPSource(cfg AMQPConfig) *AMQPSource {
+	return &AMQPSource{cfg: cfg, stopped: make(chan struct{})}
+}
+
+// ReconnectCount returns how many times the connection has been
+// re-established since Start.
+func (s *AMQPSource) ReconnectCount() uint64 {
+	return atomic.LoadUint64(&s.reconnectCount)
+}
+
+// Start implements msgsource.MessageSource.
+func (s *AMQPSource) Start(ctx context.Context) (<-chan RawMessage, error) {
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+
+	out := make(chan RawMessage)
+	go s.run(ctx, out)
+	return out, nil
+}
+
+func (s *AMQPSource) deadLetterEnabled() bool {
+	return s.cfg.DeadLetterExchange != "" && s.cfg.DeadLetterQueue != ""
+}
+
+func (s *AMQPSource) prefetchCount() int {
+	if s.cfg.PrefetchCount <= 0 {
+		return 1
+	}
+	return s.cfg.PrefetchCount
+}
+
+func (s *AMQPSource) reconnectDelay() time.Duration {
+	if s.cfg.ReconnectInterval <= 0 {
+		return defaultReconnectInterval
+	}
+	return s.cfg.ReconnectInterval
+}
+
+// connect dials the broker, declares the dead-letter topology (if any),
+// the queue, the exchange binding (if any) and Qos, replacing any
+// previous connection/channel held by s.
+func (s *AMQPSource) connect() error {
+	conn, err := amqp.Dial(s.cfg.Address)
+	if err != nil {
+		return fmt.Errorf("%v: Failed to connect to RabbitMQ", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("%v: Failed to open a channel", err)
+	}
+
+	var queueArgs amqp.Table
+	if s.deadLetterEnabled() {
+		if err := ch.ExchangeDeclare(s.cfg.DeadLetterExchange, "fanout", true, false, false, false, nil); err != nil {
+			ch.Close()
+			conn.Close()
+			return fmt.Errorf("%v: Failed to declare dead-letter exchange", err)
+		}
+		if _, err := ch.QueueDeclare(s.cfg.DeadLetterQueue, true, false, false, false, nil); err != nil {
+			ch.Close()
+			conn.Close()
+			return fmt.Errorf("%v: Failed to declare dead-letter queue", err)
+		}
+		if err := ch.QueueBind(s.cfg.DeadLetterQueue, "", s.cfg.DeadLetterExchange, false, nil); err != nil {
+			ch.Close()
+			conn.Close()
+			return fmt.Errorf("%v: Failed to bind dead-letter queue", err)
+		}
+		queueArgs = amqp.Table{"x-dead-letter-exchange": s.cfg.DeadLetterExchange}
+	}
+
+	q, err := ch.QueueDeclare(s.cfg.QueueName, s.cfg.Durable, s.cfg.AutoDelete, s.cfg.Exclusive, false, queueArgs)
+	if err != nil {
+		ch.Close()
+		conn.Close()
+		return fmt.Errorf("%v: Failed to declare a queue", err)
+	}
+
+	if s.cfg.Exchange != "" {
+		exchangeType := s.cfg.ExchangeType
+		if exchangeType == "" {
+			exchangeType = "topic"
+		}
+		if err := ch.ExchangeDeclare(s.cfg.Exchange, exchangeType, true, false, false, false, nil); err != nil {
+			ch.Close()
+			conn.Close()
+			return fmt.Errorf("%v: Failed to declare exchange", err)
+		}
+		if err := ch.QueueBind(q.Name, s.cfg.RoutingKey, s.cfg.Exchange, false, nil); err != nil {
+			ch.Close()
+			conn.Close()
+			return fmt.Errorf("%v: Failed to bind queue to exchange", err)
+		}
+	}
+
+	if err := ch.Qos(s.prefetchCount(), s.cfg.PrefetchSize, false); err != nil {
+		ch.Close()
+		conn.Close()
+		return fmt.Errorf("%v: failed to set Qos", err)
+	}
+
+	s.mu.Lock()
+	s.conn = conn
+	s.ch = ch
+	s.q = q
+	s.mu.Unlock()
+	return nil
+}
+
+// run consumes from the current channel, forwarding deliveries to out,
+// until the channel or connection closes; it then reconnects with
+// exponential backoff and resumes, until ctx is done or Stop is called.
+func (s *AMQPSource) run(ctx context.Context, out chan<- RawMessage) {
+	defer close(out)
+
+	for {
+		s.mu.Lock()
+		conn, ch, q := s.conn, s.ch, s.q
+		s.mu.Unlock()
+
+		connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+		chClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+		msgs, err := ch.Consume(q.Name, "", false, s.cfg.Exclusive, false, false, nil)
+		if err == nil {
+			s.forward(ctx, msgs, out, connClosed, chClosed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopped:
+			return
+		default:
+		}
+
+		log.Printf("msgsource: amqp connection lost, reconnecting")
+
+		if !s.reconnect(ctx) {
+			return
+		}
+		atomic.AddUint64(&s.reconnectCount, 1)
+	}
+}
+
+// reconnect retries connect with exponential backoff until one succeeds,
+// or ctx is done / Stop is called. It reports the outcome via its bool
+// return rather than looping run's caller back through a Consume on the
+// stale, already-closed conn/ch/q from before the failure - that would
+// both fail immediately and double-count reconnectCount for the same
+// outage.
+func (s *AMQPSource) reconnect(ctx context.Context) bool {
+	delay := s.reconnectDelay()
+	for {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return false
+		case <-s.stopped:
+			return false
+		}
+
+		if err := s.connect(); err == nil {
+			return true
+		}
+
+		delay *= 2
+		if delay > maxReconnectInterval {
+			delay = maxReconnectInterval
+		}
+	}
+}
+
+func (s *AMQPSource) forward(ctx context.Context, msgs <-chan amqp.Delivery, out chan<- RawMessage, connClosed, chClosed chan *amqp.Error) {
+	for {
+		select {
+		case d, ok := <-msgs:
+			if !ok {
+				return
+			}
+			select {
+			case out <- RawMessage{
+				Body:          d.Body,
+				Headers:       d.Headers,
+				ReplyTo:       d.ReplyTo,
+				CorrelationID: d.CorrelationId,
+				handle:        d,
+			}:
+			case <-ctx.Done():
+				return
+			case <-s.stopped:
+				return
+			}
+		case <-connClosed:
+			return
+		case <-chClosed:
+			return
+		case <-ctx.Done():
+			return
+		case <-s.stopped:
+			return
+		}
+	}
+}
+
+// Ack implements msgsource.MessageSource.
+func (s *AMQPSource) Ack(msg RawMessage) {
+	if d, ok := msg.handle.(amqp.Delivery); ok {
+		d.Ack(false)
+	}
+}
+
+// Nack implements msgsource.MessageSource. The message is rejected
+// without requeue, so it's routed to the dead-letter exchange when one
+// is configured.
+func (s *AMQPSource) Nack(msg RawMessage) {
+	if d, ok := msg.handle.(amqp.Delivery); ok {
+		d.Nack(false, false)
+	}
+}
+
+// Publish publishes body to the default exchange routed to replyTo with
+// the given correlation id, for answering RPC-style requests. It's not
+// part of the MessageSource interface since reply/request is an
+// AMQP-specific capability.
+func (s *AMQPSource) Publish(replyTo, correlationID string, body []byte) error {
+	s.mu.Lock()
+	ch := s.ch
+	s.mu.Unlock()
+
+	return ch.Publish("", replyTo, false, false, amqp.Publishing{
+		ContentType:   "application/json",
+		CorrelationId: correlationID,
+		Body:          body,
+	})
+}
+
+// Stop implements msgsource.MessageSource.
+func (s *AMQPSource) Stop() {
+	close(s.stopped)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ch != nil {
+		s.ch.Close()
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
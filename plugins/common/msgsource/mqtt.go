@@ -0,0 +1,104 @@
+package msgsource
+
+import (
+	"context"
+	"fmt"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// MQTTConfig configures an MQTTSource.
+type MQTTConfig struct {
+	Servers  []string
+	Topic    string
+	ClientID string
+	// QoS is the subscription quality of service, 0, 1 or 2. Combined
+	// with CleanSession=false this gives at-least-once (QoS 1) or
+	// exactly-once (QoS 2) delivery across reconnects.
+	QoS byte
+}
+
+// MQTTSource is a msgsource.MessageSource backed by an MQTT topic
+// subscription, using a persistent session (CleanSession=false) so
+// queued QoS 1/2 messages survive a broker-side disconnect.
+type MQTTSource struct {
+	cfg    MQTTConfig
+	client mqtt.Client
+	out    chan RawMessage
+	done   chan struct{}
+}
+
+// NewMQTTSource creates an MQTTSource from cfg. Connecting happens in
+// Start.
+func NewMQTTSource(cfg MQTTConfig) *MQTTSource {
+	return &MQTTSource{cfg: cfg}
+}
+
+// Start implements msgsource.MessageSource.
+func (s *MQTTSource) Start(ctx context.Context) (<-chan RawMessage, error) {
+	out := make(chan RawMessage)
+	done := make(chan struct{})
+	s.out = out
+	s.done = done
+
+	opts := mqtt.NewClientOptions()
+	for _, server := range s.cfg.Servers {
+		opts.AddBroker(server)
+	}
+	opts.SetClientID(s.cfg.ClientID)
+	opts.SetCleanSession(false)
+	opts.SetAutoReconnect(true)
+	opts.SetAutoAckDisabled(true)
+
+	client := mqtt.NewClient(opts)
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		return nil, fmt.Errorf("mqtt: failed to connect: %v", token.Error())
+	}
+	s.client = client
+
+	handler := func(_ mqtt.Client, msg mqtt.Message) {
+		select {
+		case out <- RawMessage{Body: msg.Payload(), handle: msg}:
+		case <-ctx.Done():
+		case <-done:
+		}
+	}
+
+	if token := client.Subscribe(s.cfg.Topic, s.cfg.QoS, handler); token.Wait() && token.Error() != nil {
+		client.Disconnect(250)
+		return nil, fmt.Errorf("mqtt: failed to subscribe to %q: %v", s.cfg.Topic, token.Error())
+	}
+
+	return out, nil
+}
+
+// Ack implements msgsource.MessageSource. Acking only after the caller
+// has successfully processed msg (rather than as soon as it's handed off
+// in the subscribe handler) is what makes the QoS 1/2 + CleanSession=false
+// durability claim above actually hold: if telegraf dies first, the
+// broker redelivers the message on reconnect instead of it being lost.
+func (s *MQTTSource) Ack(msg RawMessage) {
+	if m, ok := msg.handle.(mqtt.Message); ok {
+		m.Ack()
+	}
+}
+
+// Nack implements msgsource.MessageSource. paho doesn't expose a
+// separate "reject" RPC for MQTT QoS semantics, so a nacked message is
+// simply left unacked; the broker redelivers it once the session
+// reconnects, the same as a message telegraf never got to ack at all.
+func (s *MQTTSource) Nack(RawMessage) {}
+
+// Stop implements msgsource.MessageSource.
+func (s *MQTTSource) Stop() {
+	if s.done != nil {
+		close(s.done)
+	}
+	if s.client != nil {
+		s.client.Unsubscribe(s.cfg.Topic)
+		s.client.Disconnect(250)
+	}
+	if s.out != nil {
+		close(s.out)
+	}
+}
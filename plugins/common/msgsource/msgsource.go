@@ -0,0 +1,44 @@
+// Package msgsource provides a broker-agnostic message stream abstraction
+// shared by telegraf's rabbit_mq_parser, nats_parser and mqtt_parser
+// inputs, so the Zabbix/JSON/influx_line parser and key-mapper subsystem
+// in plugins/inputs/rabbit_mq_parser/parsers only has to be wired up
+// against raw bytes once.
+package msgsource
+
+import "context"
+
+// RawMessage is a single inbound message, decoupled from any particular
+// broker's delivery type.
+type RawMessage struct {
+	Body []byte
+
+	// Headers carries transport-specific metadata (e.g. AMQP's
+	// "x-death"); nil for transports that don't have any.
+	Headers map[string]interface{}
+
+	// ReplyTo and CorrelationID are populated for transports that
+	// support request/reply (currently just AMQP); empty otherwise.
+	ReplyTo       string
+	CorrelationID string
+
+	// handle is the source-specific delivery handle needed to
+	// Ack/Nack this exact message. Only the MessageSource that
+	// produced it interprets it.
+	handle interface{}
+}
+
+// MessageSource is a broker-agnostic inbound message stream. Start
+// returns a channel of RawMessage that stays open across broker-level
+// disconnects - each implementation reconnects internally (AMQPSource
+// explicitly, NATSSource/MQTTSource via their client libraries' built-in
+// auto-reconnect) without the caller having to notice or retry Start.
+// The channel is closed once Stop is called (AMQPSource also closes it
+// when the ctx passed to Start is done). Ack/Nack acknowledge
+// or reject a RawMessage previously received from the same
+// MessageSource.
+type MessageSource interface {
+	Start(ctx context.Context) (<-chan RawMessage, error)
+	Ack(msg RawMessage)
+	Nack(msg RawMessage)
+	Stop()
+}
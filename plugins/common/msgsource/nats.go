@@ -0,0 +1,97 @@
+package msgsource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSConfig configures a NATSSource.
+type NATSConfig struct {
+	Servers []string
+	Subject string
+	// QueueGroup, if set, makes this subscription part of a NATS queue
+	// group so multiple telegraf instances can share load across the
+	// same Subject instead of each receiving every message.
+	QueueGroup string
+}
+
+// NATSSource is a msgsource.MessageSource backed by a NATS subject
+// subscription. NATS has no broker-side ack/requeue concept for core
+// (non-JetStream) subscriptions, so Ack/Nack are both no-ops; delivery
+// is at-most-once, same as a plain nats.Subscribe callback.
+type NATSSource struct {
+	cfg NATSConfig
+
+	conn *nats.Conn
+	sub  *nats.Subscription
+	out  chan RawMessage
+	done chan struct{}
+}
+
+// NewNATSSource creates a NATSSource from cfg. Connecting happens in
+// Start.
+func NewNATSSource(cfg NATSConfig) *NATSSource {
+	return &NATSSource{cfg: cfg}
+}
+
+// Start implements msgsource.MessageSource.
+func (s *NATSSource) Start(ctx context.Context) (<-chan RawMessage, error) {
+	conn, err := nats.Connect(nats.DefaultURL, nats.Servers(s.cfg.Servers))
+	if err != nil {
+		return nil, fmt.Errorf("nats: failed to connect: %v", err)
+	}
+	s.conn = conn
+
+	out := make(chan RawMessage)
+	done := make(chan struct{})
+	s.done = done
+	s.out = out
+
+	handler := func(msg *nats.Msg) {
+		select {
+		case out <- RawMessage{Body: msg.Data}:
+		case <-ctx.Done():
+		case <-done:
+		}
+	}
+
+	var sub *nats.Subscription
+	if s.cfg.QueueGroup != "" {
+		sub, err = conn.QueueSubscribe(s.cfg.Subject, s.cfg.QueueGroup, handler)
+	} else {
+		sub, err = conn.Subscribe(s.cfg.Subject, handler)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats: failed to subscribe to %q: %v", s.cfg.Subject, err)
+	}
+	s.sub = sub
+
+	return out, nil
+}
+
+// Ack implements msgsource.MessageSource. Core NATS subscriptions have no
+// broker-side ack, so this is a no-op.
+func (s *NATSSource) Ack(RawMessage) {}
+
+// Nack implements msgsource.MessageSource. Core NATS subscriptions have
+// no broker-side redelivery, so this is a no-op.
+func (s *NATSSource) Nack(RawMessage) {}
+
+// Stop implements msgsource.MessageSource.
+func (s *NATSSource) Stop() {
+	if s.sub != nil {
+		s.sub.Unsubscribe()
+	}
+	if s.done != nil {
+		close(s.done)
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	if s.out != nil {
+		close(s.out)
+	}
+}
@@ -0,0 +1,154 @@
+// Package mqtt_parser is a lightweight-broker sibling of
+// rabbit_mq_parser: same payload_format/key_mapping_file parser
+// subsystem, but subscribed over MQTT instead of RabbitMQ, for edge
+// deployments that want QoS 1/2 durability without a full AMQP broker.
+package mqtt_parser
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/msgsource"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/inputs/rabbit_mq_parser/parsers"
+)
+
+// MQTTParser is the top level struct for this plugin.
+type MQTTParser struct {
+	Servers  []string
+	Topic    string
+	ClientID string
+	// QoS is the subscription quality of service: 0, 1 or 2. Combined
+	// with the persistent session this plugin always requests, QoS 1/2
+	// messages queued while telegraf is down are delivered on reconnect.
+	QoS byte
+
+	// PayloadFormat and KeyMappingFile have the same meaning as on
+	// rabbit_mq_parser; see plugins/inputs/rabbit_mq_parser/parsers.
+	PayloadFormat  string
+	KeyMappingFile string
+
+	source *msgsource.MQTTSource
+	cancel context.CancelFunc
+	parser parsers.MessageParser
+
+	parseErrors uint64
+}
+
+// Description satisfies the telegraf.ServiceInput interface
+func (m *MQTTParser) Description() string {
+	return "MQTT client sharing rabbit_mq_parser's message-format parser subsystem"
+}
+
+// SampleConfig satisfies the telegraf.ServiceInput interface
+func (m *MQTTParser) SampleConfig() string {
+	return `
+  ## MQTT servers to connect to.
+  servers = ["tcp://localhost:1883"]
+  topic = "telegraf"
+  client_id = "telegraf-mqtt-parser"
+
+  ## Subscription QoS. 1 or 2 gives durable delivery across reconnects.
+  # qos = 1
+
+  ## Message format of the topic's payloads. One of "zabbix_pyrepr",
+  ## "json" or "influx_line".
+  # payload_format = "zabbix_pyrepr"
+
+  ## Optional TOML file of regex -> measurement/tag/field rules. See
+  ## plugins/inputs/rabbit_mq_parser/keymap.toml.example.
+  # key_mapping_file = "/etc/telegraf/mqtt_parser_keymap.toml"
+`
+}
+
+// Gather satisfies the telegraf.ServiceInput interface. Connection
+// handling happens in Start/listen; Gather only reports self metrics.
+func (m *MQTTParser) Gather(acc telegraf.Accumulator) error {
+	acc.AddFields("mqtt_parser",
+		map[string]interface{}{"parse_errors": atomic.LoadUint64(&m.parseErrors)},
+		map[string]string{"topic": m.Topic},
+	)
+	return nil
+}
+
+func (m *MQTTParser) buildParser() (parsers.MessageParser, error) {
+	format := m.PayloadFormat
+	if format == "" {
+		format = "zabbix_pyrepr"
+	}
+
+	mapper, err := parsers.BuildMapper(m.KeyMappingFile)
+	if err != nil {
+		return nil, err
+	}
+	return parsers.NewParser(format, mapper)
+}
+
+// Start satisfies the telegraf.ServiceInput interface
+func (m *MQTTParser) Start(acc telegraf.Accumulator) error {
+	parser, err := m.buildParser()
+	if err != nil {
+		return err
+	}
+	m.parser = parser
+
+	m.source = msgsource.NewMQTTSource(msgsource.MQTTConfig{
+		Servers:  m.Servers,
+		Topic:    m.Topic,
+		ClientID: m.ClientID,
+		QoS:      m.QoS,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	msgs, err := m.source.Start(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+	go m.listen(msgs, acc)
+
+	log.Println("Starting MQTT service...")
+	return nil
+}
+
+func (m *MQTTParser) listen(msgs <-chan msgsource.RawMessage, acc telegraf.Accumulator) {
+	for msg := range msgs {
+		go m.handleMessage(msg, acc)
+	}
+}
+
+// handleMessage parses the incoming message body and adds the resulting
+// metrics to the Accumulator. The message is only acked on success; a
+// parse failure leaves it unacked so the broker redelivers it once the
+// session reconnects, instead of acking it up front and losing it if
+// telegraf dies mid-processing.
+func (m *MQTTParser) handleMessage(msg msgsource.RawMessage, acc telegraf.Accumulator) {
+	metrics, err := m.parser.Parse(msg.Body)
+	if err != nil {
+		atomic.AddUint64(&m.parseErrors, 1)
+		acc.AddError(fmt.Errorf("mqtt_parser: %v", err))
+		m.source.Nack(msg)
+		return
+	}
+	for _, metric := range metrics {
+		acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
+	}
+	m.source.Ack(msg)
+}
+
+// Stop satisfies the telegraf.ServiceInput interface
+func (m *MQTTParser) Stop() {
+	m.cancel()
+	m.source.Stop()
+}
+
+func init() {
+	inputs.Add("mqtt_parser", func() telegraf.Input {
+		return &MQTTParser{}
+	})
+}
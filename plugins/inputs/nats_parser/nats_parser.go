@@ -0,0 +1,145 @@
+// Package nats_parser is a lightweight-broker sibling of rabbit_mq_parser:
+// same payload_format/key_mapping_file parser subsystem, but subscribed
+// over NATS instead of RabbitMQ, for edge deployments that don't want to
+// run a full AMQP broker.
+package nats_parser
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/msgsource"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/inputs/rabbit_mq_parser/parsers"
+)
+
+// NATSParser is the top level struct for this plugin.
+type NATSParser struct {
+	Servers []string
+	Subject string
+	// QueueGroup, if set, shares Subject's messages across every
+	// telegraf instance using the same group name instead of each
+	// receiving every message, for horizontal scale-out.
+	QueueGroup string
+
+	// PayloadFormat and KeyMappingFile have the same meaning as on
+	// rabbit_mq_parser; see plugins/inputs/rabbit_mq_parser/parsers.
+	PayloadFormat  string
+	KeyMappingFile string
+
+	source *msgsource.NATSSource
+	cancel context.CancelFunc
+	parser parsers.MessageParser
+
+	parseErrors uint64
+}
+
+// Description satisfies the telegraf.ServiceInput interface
+func (n *NATSParser) Description() string {
+	return "NATS client sharing rabbit_mq_parser's message-format parser subsystem"
+}
+
+// SampleConfig satisfies the telegraf.ServiceInput interface
+func (n *NATSParser) SampleConfig() string {
+	return `
+  ## NATS servers to connect to.
+  servers = ["nats://localhost:4222"]
+  subject = "telegraf"
+
+  ## Share subject's messages across every telegraf instance using this
+  ## group name, instead of each receiving every message.
+  # queue_group = ""
+
+  ## Message format of the subject's payloads. One of "zabbix_pyrepr",
+  ## "json" or "influx_line".
+  # payload_format = "zabbix_pyrepr"
+
+  ## Optional TOML file of regex -> measurement/tag/field rules. See
+  ## plugins/inputs/rabbit_mq_parser/keymap.toml.example.
+  # key_mapping_file = "/etc/telegraf/nats_parser_keymap.toml"
+`
+}
+
+// Gather satisfies the telegraf.ServiceInput interface. Connection
+// handling happens in Start/listen; Gather only reports self metrics.
+func (n *NATSParser) Gather(acc telegraf.Accumulator) error {
+	acc.AddFields("nats_parser",
+		map[string]interface{}{"parse_errors": atomic.LoadUint64(&n.parseErrors)},
+		map[string]string{"subject": n.Subject},
+	)
+	return nil
+}
+
+func (n *NATSParser) buildParser() (parsers.MessageParser, error) {
+	format := n.PayloadFormat
+	if format == "" {
+		format = "zabbix_pyrepr"
+	}
+
+	mapper, err := parsers.BuildMapper(n.KeyMappingFile)
+	if err != nil {
+		return nil, err
+	}
+	return parsers.NewParser(format, mapper)
+}
+
+// Start satisfies the telegraf.ServiceInput interface
+func (n *NATSParser) Start(acc telegraf.Accumulator) error {
+	parser, err := n.buildParser()
+	if err != nil {
+		return err
+	}
+	n.parser = parser
+
+	n.source = msgsource.NewNATSSource(msgsource.NATSConfig{
+		Servers:    n.Servers,
+		Subject:    n.Subject,
+		QueueGroup: n.QueueGroup,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+
+	msgs, err := n.source.Start(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+	go n.listen(msgs, acc)
+
+	log.Println("Starting NATS service...")
+	return nil
+}
+
+func (n *NATSParser) listen(msgs <-chan msgsource.RawMessage, acc telegraf.Accumulator) {
+	for msg := range msgs {
+		go n.handleMessage(msg, acc)
+	}
+}
+
+func (n *NATSParser) handleMessage(msg msgsource.RawMessage, acc telegraf.Accumulator) {
+	metrics, err := n.parser.Parse(msg.Body)
+	if err != nil {
+		atomic.AddUint64(&n.parseErrors, 1)
+		acc.AddError(fmt.Errorf("nats_parser: %v", err))
+		return
+	}
+	for _, m := range metrics {
+		acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+	}
+}
+
+// Stop satisfies the telegraf.ServiceInput interface
+func (n *NATSParser) Stop() {
+	n.cancel()
+	n.source.Stop()
+}
+
+func init() {
+	inputs.Add("nats_parser", func() telegraf.Input {
+		return &NATSParser{}
+	})
+}
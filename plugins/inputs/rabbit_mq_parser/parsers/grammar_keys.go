@@ -0,0 +1,50 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GrammarKeyMapper structures a key using the typed AST produced by
+// ParseKey instead of LegacyKeyMapper's strings.Split decision tree. It
+// exists primarily to handle the key shapes LegacyKeyMapper gives up on
+// (see its doc comment) - most notably keys with a nested key as one of
+// their bracket parameters, e.g.
+// grpavg["app-x","system.cpu.util[,user]",last,0].
+type GrammarKeyMapper struct{}
+
+// Map implements KeyMapper.
+func (GrammarKeyMapper) Map(key string, value interface{}) (string, map[string]string, map[string]interface{}, error) {
+	k, err := ParseKey(key)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("grammar key mapper: %v", err)
+	}
+
+	meas := strings.Join(k.Namespace, ".")
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+
+	if len(k.Params) == 0 {
+		fields["value"] = value
+		return meas, tags, fields, nil
+	}
+
+	var field []string
+	for i, param := range k.Params {
+		if param.Kind == ParamKey {
+			// A nested key as a parameter (rather than a plain string)
+			// identifies what this value was computed over, e.g. the
+			// inner "system.cpu.util[,user]" that grpavg[...] averages.
+			tags[fmt.Sprintf("param%d_key", i)] = param.Key.String()
+			continue
+		}
+		field = append(field, param.Str)
+	}
+
+	if len(field) == 0 {
+		fields["value"] = value
+	} else {
+		fields[strings.Join(field, ".")] = value
+	}
+	return meas, tags, fields, nil
+}
@@ -0,0 +1,34 @@
+package parsers
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+)
+
+func init() {
+	Add("influx_line", func(mapper KeyMapper) MessageParser {
+		return &influxLineParser{parser: influx.NewParser(influx.NewMetricHandler())}
+	})
+}
+
+// influxLineParser accepts messages already in InfluxDB line protocol,
+// for producers that don't want to speak Zabbix or JSON at all. The
+// KeyMapper is unused since line protocol already carries structured
+// measurement/tags/fields.
+type influxLineParser struct {
+	parser *influx.Parser
+}
+
+// Parse implements MessageParser.
+func (p *influxLineParser) Parse(body []byte) ([]telegraf.Metric, error) {
+	metrics, err := p.parser.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("influx_line: %v", err)
+	}
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("influx_line: message contains no metrics")
+	}
+	return metrics, nil
+}
@@ -0,0 +1,39 @@
+package parsers
+
+import "testing"
+
+func TestInfluxLineParserParse(t *testing.T) {
+	creator, ok := creators["influx_line"]
+	if !ok {
+		t.Fatal(`"influx_line" parser not registered`)
+	}
+	p := creator(nil) // the KeyMapper is unused by this parser
+
+	metrics, err := p.Parse([]byte("cpu,host=web01 util=12.3 1500000000000000000"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(metrics) != 1 {
+		t.Fatalf("got %d metrics, want 1", len(metrics))
+	}
+	if metrics[0].Name() != "cpu" {
+		t.Errorf("Name() = %q, want %q", metrics[0].Name(), "cpu")
+	}
+}
+
+func TestInfluxLineParserParseMalformed(t *testing.T) {
+	creator := creators["influx_line"]
+	p := creator(nil)
+
+	cases := []string{
+		"",
+		"not line protocol",
+		"cpu,host=web01",
+		"\x00\xff\xfe",
+	}
+	for _, body := range cases {
+		if _, err := p.Parse([]byte(body)); err == nil {
+			t.Errorf("Parse(%q): expected an error, got nil", body)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+func init() {
+	Add("json", func(mapper KeyMapper) MessageParser {
+		return &jsonParser{mapper: mapper}
+	})
+}
+
+// jsonMessage is the expected shape of a "json" payload_format message:
+// the same fields the zabbix_pyrepr format carries, just proper JSON
+// instead of a Python repr.
+type jsonMessage struct {
+	Host   string      `json:"host"`
+	Server string      `json:"server"`
+	Clock  int64       `json:"clock"`
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+}
+
+// jsonParser parses newline-free JSON objects, one metric per message.
+type jsonParser struct {
+	mapper KeyMapper
+}
+
+// Parse implements MessageParser.
+func (p *jsonParser) Parse(body []byte) ([]telegraf.Metric, error) {
+	var msg jsonMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("json: %v", err)
+	}
+
+	meas, tags, fields, err := p.mapper.Map(msg.Key, msg.Value)
+	if err != nil {
+		return nil, fmt.Errorf("json: mapping key %q: %v", msg.Key, err)
+	}
+	tags["host"] = msg.Host
+	tags["server"] = msg.Server
+
+	m, err := telegraf.NewMetric(meas, tags, fields, time.Unix(msg.Clock, 0))
+	if err != nil {
+		return nil, fmt.Errorf("json: building metric: %v", err)
+	}
+	return []telegraf.Metric{m}, nil
+}
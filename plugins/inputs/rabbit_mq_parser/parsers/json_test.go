@@ -0,0 +1,67 @@
+package parsers
+
+import (
+	"fmt"
+	"testing"
+)
+
+// fakeMapper is a KeyMapper test double that returns a fixed mapping, or
+// its err when set, regardless of the key/value passed in.
+type fakeMapper struct {
+	meas   string
+	tags   map[string]string
+	fields map[string]interface{}
+	err    error
+}
+
+func (m fakeMapper) Map(string, interface{}) (string, map[string]string, map[string]interface{}, error) {
+	if m.err != nil {
+		return "", nil, nil, m.err
+	}
+	return m.meas, m.tags, m.fields, nil
+}
+
+func TestJSONParserParse(t *testing.T) {
+	mapper := fakeMapper{meas: "cpu", tags: map[string]string{}, fields: map[string]interface{}{"util": 12.3}}
+	p := &jsonParser{mapper: mapper}
+
+	metrics, err := p.Parse([]byte(`{"host":"web01","server":"zbx01","clock":1500000000,"key":"system.cpu.util","value":12.3}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(metrics) != 1 {
+		t.Fatalf("got %d metrics, want 1", len(metrics))
+	}
+	m := metrics[0]
+	if m.Name() != "cpu" {
+		t.Errorf("Name() = %q, want %q", m.Name(), "cpu")
+	}
+	if m.Tags()["host"] != "web01" || m.Tags()["server"] != "zbx01" {
+		t.Errorf("Tags() = %v, missing host/server", m.Tags())
+	}
+}
+
+func TestJSONParserParseMalformed(t *testing.T) {
+	p := &jsonParser{mapper: fakeMapper{}}
+
+	cases := []string{
+		"",
+		"not json",
+		"{",
+		`{"host": }`,
+		"\x00\xff\xfe",
+	}
+	for _, body := range cases {
+		if _, err := p.Parse([]byte(body)); err == nil {
+			t.Errorf("Parse(%q): expected an error, got nil", body)
+		}
+	}
+}
+
+func TestJSONParserParseMapperError(t *testing.T) {
+	p := &jsonParser{mapper: fakeMapper{err: fmt.Errorf("no mapping")}}
+
+	if _, err := p.Parse([]byte(`{"host":"web01","server":"zbx01","clock":1500000000,"key":"x","value":1}`)); err == nil {
+		t.Fatal("Parse: expected the mapper's error to propagate, got nil")
+	}
+}
@@ -0,0 +1,49 @@
+package parsers
+
+import "fmt"
+
+// KeyMapper structures a format-specific metric key (e.g. a Zabbix item
+// key such as "system.cpu.util[,user]") into an Influx-style
+// measurement/tags/fields triple. Parsers that already carry structured
+// data (json, influx_line) don't need one.
+type KeyMapper interface {
+	Map(key string, value interface{}) (measurement string, tags map[string]string, fields map[string]interface{}, err error)
+}
+
+// ChainKeyMapper tries each KeyMapper in order and returns the first
+// successful mapping. It's used to let a data-driven RuleKeyMapper take
+// precedence over the LegacyKeyMapper's hardcoded cases while still
+// falling back to them for keys no rule covers yet.
+type ChainKeyMapper []KeyMapper
+
+// Map implements KeyMapper.
+func (c ChainKeyMapper) Map(key string, value interface{}) (string, map[string]string, map[string]interface{}, error) {
+	var lastErr error
+	for _, mapper := range c {
+		meas, tags, fields, err := mapper.Map(key, value)
+		if err == nil {
+			return meas, tags, fields, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("rabbit_mq_parser: no key mapper configured")
+	}
+	return "", nil, nil, lastErr
+}
+
+// BuildMapper builds the default KeyMapper chain shared by
+// rabbit_mq_parser, nats_parser and mqtt_parser: a RuleKeyMapper loaded
+// from keyMappingFile (if set) tried first, falling back to
+// LegacyKeyMapper and then GrammarKeyMapper. keyMappingFile may be empty,
+// in which case the rule mapper is omitted entirely.
+func BuildMapper(keyMappingFile string) (KeyMapper, error) {
+	if keyMappingFile == "" {
+		return ChainKeyMapper{LegacyKeyMapper{}, GrammarKeyMapper{}}, nil
+	}
+	ruleMapper, err := LoadRuleKeyMapper(keyMappingFile)
+	if err != nil {
+		return nil, err
+	}
+	return ChainKeyMapper{ruleMapper, LegacyKeyMapper{}, GrammarKeyMapper{}}, nil
+}
@@ -0,0 +1,280 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LegacyKeyMapper reproduces the original hardcoded decision tree that
+// this plugin shipped with before the KeyMapper interface existed. It's
+// kept as a fallback so existing deployments keep working without a
+// mapping file, but new key shapes should be added to a RuleKeyMapper's
+// ruleset instead of growing this switch further.
+//
+// Keys that fall through to one of structureKey's "default" cases -
+// chiefly anything with more than one bracket group, e.g.
+// grpavg["app-x","system.cpu.util[,user]",last,0] - aren't structured
+// confidently here; Map returns an error for those so a ChainKeyMapper
+// can fall back to GrammarKeyMapper instead of silently storing the raw
+// key as the measurement.
+type LegacyKeyMapper struct{}
+
+// Map implements KeyMapper.
+func (LegacyKeyMapper) Map(key string, value interface{}) (string, map[string]string, map[string]interface{}, error) {
+	meas, tags, fields, matched := structureKey(key, value)
+	if !matched {
+		return "", nil, nil, fmt.Errorf("legacy key mapper: no confident structuring for key %q", key)
+	}
+	return meas, tags, fields, nil
+}
+
+// This is an awful decision tree parsing, but it works...
+// Need to hone with more data
+func structureKey(key string, value interface{}) (string, map[string]string, map[string]interface{}, bool) {
+	// Beginning of Influx point
+	meas := ""
+	tags := make(map[string]string, 0)
+	fields := make(map[string]interface{}, 0)
+	matched := true
+
+	// BracketSplit splits the metics on the "["
+	bs := strings.Split(key, "[")
+	// PeriodSplit splits the first part of the metric on "."s
+	ps := strings.Split(bs[0], ".")
+
+	// Switch on the results of the bracket split
+	switch len(bs) {
+
+	// No brackets so len(split) == 1
+	case 1:
+
+		// Switch on the results of the period split
+		switch len(ps) {
+
+		// meas.field
+		case 2:
+			meas = ps[0]
+			fields[ps[1]] = value
+
+		// meas.field*
+		case 3:
+			meas = ps[0]
+			fields[fmt.Sprintf("%v.%v", ps[1], ps[2])] = value
+
+		// meas.field.field.context
+		case 4:
+			if strings.Contains(ps[3], "-") {
+				meas = ps[0]
+				fields[fmt.Sprintf("%v.%v", ps[1], ps[2])] = value
+				tags["context"] = ps[3]
+			} else {
+				meas = ps[0]
+				fields[fmt.Sprintf("%v.%v.%v", ps[1], ps[2], ps[3])] = value
+			}
+
+		// Default
+		default:
+			meas = key
+			fields["value"] = value
+			matched = false
+		}
+
+	// Brackets so len(split) == 2
+	// longest case
+	case 2:
+
+		// Switch on the results of the period split
+		switch len(ps) {
+
+		// period split only contains measurement
+		case 1:
+			meas = ps[0]
+			bracket := trim(bs[1])
+			// Arcane parsing rules
+			switch {
+
+			// Bracket contains something like 1/40 -> ignore
+			case strings.Contains(bs[1], "/"):
+				fields["value"] = value
+
+			// bracket is field name wiht some changes
+			case strings.Contains(bs[1], ","):
+				// switch "," and " " to "."
+				bracket = rp(rp(bracket, ",", "."), " ", ".")
+				fields[bracket] = value
+
+			// Default
+			default:
+				meas = key
+				fields["value"] = value
+				matched = false
+			}
+
+		// period split contains more information as well as brackets
+		case 2:
+			meas = ps[0]
+			bracket := trim(bs[1])
+			// Switch on length of bracket
+			switch {
+
+			// short brakets
+			case len(bracket) < 10:
+				bracket = rp(bracket, ",", "")
+				if bracket != "" {
+					tags["process"] = bracket
+				}
+				fields[ps[1]] = value
+
+			// medium brakets
+			case len(bracket) < 25:
+				// remove all {,}," from bracket
+				bracket = rp(rp(rp(bracket, "\"", ""), "{", ""), "}", "")
+				fields[bracket] = value
+
+			// long brackets are system.run[curl ....]
+			case len(bracket) > 25 && len(bracket) < 150:
+				fields[ps[1]] = bracket
+				tags["status_code"] = fmt.Sprint(value)
+
+			// Default
+			default:
+				meas = key
+				fields["value"] = value
+				matched = false
+			}
+
+		// len(period_split) == 3 and contains more information
+		case 3:
+			meas = ps[0]
+			bracket := trim(bs[1])
+
+			// Switch on bracket content
+			switch {
+
+			// bracket contains context
+			case strings.Contains(bracket, "-"):
+				fields[jwp(ps[1], ps[2])] = value
+				tags["context"] = bracket
+
+			// bracket contains file system info
+			case strings.Contains(bracket, "/"):
+				t := strings.Split(bracket, ",")
+				tags["path"] = t[0]
+				fields[jw2p(ps[1], ps[2], t[1])] = value
+
+			// TODO: find a non default case that fits all "net","system","vm" meass down here
+			default:
+				bracketCommaSplit := strings.Split(bracket, ",")
+
+				// Switch on bracket contents then measurement (set at the top of this case)
+				switch {
+
+				// system cpu and swap meas
+				case bracketCommaSplit[0] == "":
+					fields[jwp(ps[1], bracketCommaSplit[1])] = value
+
+				// net meas
+				case meas == "net":
+					tags["interface"] = bracketCommaSplit[0]
+					if len(bracketCommaSplit) > 1 {
+						fields[jw2p(ps[1], ps[2], bracketCommaSplit[1])] = value
+					} else {
+						fields[jwp(ps[1], ps[2])] = value
+					}
+
+				// vm measurement
+				case meas == "vm":
+					fields[jw2p(ps[1], ps[2], bracketCommaSplit[0])] = value
+
+				// system measurment
+				case meas == "system":
+					// for per-cpu metrics we need to pull out cpu as tag
+					if ps[1] == "cpu" {
+						fields[jw2p(ps[1], ps[2], bracketCommaSplit[0])] = value
+						tags["cpu"] = bracketCommaSplit[1]
+					} else {
+						// For system health checks we need to store system checked (mem, disk, cpu, etc...) with diff tags
+						fields[jwp(ps[1], ps[2])] = value
+						tags["system"] = bracketCommaSplit[0]
+					}
+
+				// web measurement
+				case meas == "web":
+					meas = jwp(ps[0], ps[1])
+					if ps[2] == "time" {
+						fields["value"] = value
+					} else {
+						fields[ps[2]] = value
+					}
+					tags["system"] = "ZabbixGUI"
+
+				// Default
+				default:
+					meas = key
+					fields["value"] = value
+					matched = false
+				}
+			}
+
+		// len(period_split) == 5 and contains most of the metadata
+		case 5:
+			meas = ps[0]
+			bracket := trim(bs[1])
+			// Switch on measurement name
+			switch {
+
+			// custom measurement -> custom.vfs.dev
+			case meas == "custom":
+				meas = jw2p(ps[0], ps[1], ps[2])
+				tags["drive"] = bracket
+				fields[jwp(ps[3], ps[4])] = value
+
+			// app measurement
+			case meas == "app":
+				tags["name"] = jwp(ps[1], ps[2])
+				fields[jwp(ps[3], ps[4])] = value
+
+			// default
+			default:
+				meas = key
+				fields["value"] = value
+				matched = false
+			}
+
+		// Default case for any other len(period_split)
+		default:
+			meas = key
+			fields["value"] = value
+			matched = false
+		}
+
+	// Multiple brackets -> grpavg["app-searchautocomplete","system.cpu.util[,user]",last,0]
+	// This is exactly the shape GrammarKeyMapper exists to handle properly.
+	default:
+		meas = key
+		fields["value"] = value
+		matched = false
+	}
+	// Return the start of a point
+	return meas, tags, fields, matched
+}
+
+// join with period
+func jwp(s1, s2 string) string {
+	return fmt.Sprintf("%v.%v", s1, s2)
+}
+
+// join with 2 period
+func jw2p(s1, s2, s3 string) string {
+	return fmt.Sprintf("%v.%v.%v", s1, s2, s3)
+}
+
+// replace
+func rp(s, old, new string) string {
+	return strings.Replace(s, old, new, -1)
+}
+
+// trims last char from string
+func trim(s string) string {
+	return s[0 : len(s)-1]
+}
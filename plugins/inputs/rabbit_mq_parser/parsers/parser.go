@@ -0,0 +1,42 @@
+// Package parsers provides a pluggable set of message-format parsers for
+// the rabbit_mq_parser input. A MessageParser turns a raw AMQP message
+// body into telegraf metrics using a KeyMapper to structure the
+// measurement/tags/fields for formats (like Zabbix) that encode all of
+// that information in a single opaque key string.
+package parsers
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+)
+
+// MessageParser turns a raw message body into zero or more telegraf
+// metrics. Implementations must be safe for concurrent use; handleMessage
+// parses messages on a per-delivery goroutine.
+type MessageParser interface {
+	Parse(body []byte) ([]telegraf.Metric, error)
+}
+
+// Creator builds a new MessageParser bound to the given KeyMapper.
+// Parsers that don't need key mapping (e.g. influx_line) may ignore it.
+type Creator func(mapper KeyMapper) MessageParser
+
+var creators = map[string]Creator{}
+
+// Add registers a MessageParser under the given payload_format name so it
+// can be selected from config. Called from the init() of each parser
+// implementation.
+func Add(name string, creator Creator) {
+	creators[name] = creator
+}
+
+// NewParser instantiates the MessageParser registered under name, bound to
+// mapper. It returns an error if name was never registered via Add.
+func NewParser(name string, mapper KeyMapper) (MessageParser, error) {
+	creator, ok := creators[name]
+	if !ok {
+		return nil, fmt.Errorf("rabbit_mq_parser: unknown payload_format %q", name)
+	}
+	return creator(mapper), nil
+}
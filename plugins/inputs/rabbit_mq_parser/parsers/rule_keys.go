@@ -0,0 +1,77 @@
+package parsers
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Rule maps keys matching Pattern to a measurement/field/tags triple.
+// Measurement, Field and each entry in Tags are expanded against the
+// regexp match using Go's regexp.Regexp.ExpandString syntax ($1, $2, ...
+// or ${name} for named groups), so a single rule covers a whole family
+// of keys without any Go code.
+type Rule struct {
+	Pattern     string            `toml:"pattern"`
+	Measurement string            `toml:"measurement"`
+	Field       string            `toml:"field"`
+	Tags        map[string]string `toml:"tags"`
+
+	re *regexp.Regexp
+}
+
+// ruleFile is the on-disk shape of a key_mapping_file.
+type ruleFile struct {
+	Rule []Rule `toml:"rule"`
+}
+
+// RuleKeyMapper is a data-driven KeyMapper: its ruleset is loaded from a
+// TOML file mapping regex patterns to measurement/tag/field templates,
+// so operators can add support for new key shapes (custom.*, app.*, ...)
+// without editing Go source. Rules are tried in file order; the first
+// match wins.
+type RuleKeyMapper struct {
+	rules []Rule
+}
+
+// LoadRuleKeyMapper reads and compiles the ruleset at path.
+func LoadRuleKeyMapper(path string) (*RuleKeyMapper, error) {
+	var rf ruleFile
+	if _, err := toml.DecodeFile(path, &rf); err != nil {
+		return nil, fmt.Errorf("rabbit_mq_parser: reading key_mapping_file %q: %v", path, err)
+	}
+
+	rules := make([]Rule, 0, len(rf.Rule))
+	for _, r := range rf.Rule {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rabbit_mq_parser: compiling rule pattern %q: %v", r.Pattern, err)
+		}
+		r.re = re
+		rules = append(rules, r)
+	}
+	return &RuleKeyMapper{rules: rules}, nil
+}
+
+// Map implements KeyMapper. It returns an error if no rule matches key, so
+// that callers can chain it with a fallback mapper via ChainKeyMapper.
+func (m *RuleKeyMapper) Map(key string, value interface{}) (string, map[string]string, map[string]interface{}, error) {
+	for _, r := range m.rules {
+		match := r.re.FindStringSubmatchIndex(key)
+		if match == nil {
+			continue
+		}
+
+		meas := string(r.re.ExpandString(nil, r.Measurement, key, match))
+		field := string(r.re.ExpandString(nil, r.Field, key, match))
+
+		tags := make(map[string]string, len(r.Tags))
+		for name, tmpl := range r.Tags {
+			tags[name] = string(r.re.ExpandString(nil, tmpl, key, match))
+		}
+
+		return meas, tags, map[string]interface{}{field: value}, nil
+	}
+	return "", nil, nil, fmt.Errorf("rabbit_mq_parser: no rule matched key %q", key)
+}
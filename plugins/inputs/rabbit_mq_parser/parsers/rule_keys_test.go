@@ -0,0 +1,92 @@
+package parsers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRuleKeyMapperMap(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keymap.toml")
+	const toml = `
+[[rule]]
+pattern = "^custom\\.vfs\\.dev\\[(?P<drive>[^,]+),(?P<op>\\w+)\\]$"
+measurement = "custom.vfs.dev"
+field = "${op}"
+tags = { drive = "${drive}" }
+`
+	if err := os.WriteFile(path, []byte(toml), 0o644); err != nil {
+		t.Fatalf("writing test keymap: %v", err)
+	}
+
+	mapper, err := LoadRuleKeyMapper(path)
+	if err != nil {
+		t.Fatalf("LoadRuleKeyMapper: %v", err)
+	}
+
+	meas, tags, fields, err := mapper.Map(`custom.vfs.dev[/,read]`, 42.0)
+	if err != nil {
+		t.Fatalf("Map: %v", err)
+	}
+	if meas != "custom.vfs.dev" {
+		t.Errorf("measurement = %q, want %q", meas, "custom.vfs.dev")
+	}
+	if tags["drive"] != "/" {
+		t.Errorf("tags[drive] = %q, want %q", tags["drive"], "/")
+	}
+	if fields["read"] != 42.0 {
+		t.Errorf("fields[read] = %v, want 42.0", fields["read"])
+	}
+
+	if _, _, _, err := mapper.Map("no.match.here", 1); err == nil {
+		t.Fatal("Map: expected an error for a key with no matching rule")
+	}
+}
+
+func TestLoadRuleKeyMapperBadPattern(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keymap.toml")
+	const toml = `
+[[rule]]
+pattern = "["
+measurement = "x"
+field = "y"
+`
+	if err := os.WriteFile(path, []byte(toml), 0o644); err != nil {
+		t.Fatalf("writing test keymap: %v", err)
+	}
+
+	if _, err := LoadRuleKeyMapper(path); err == nil {
+		t.Fatal("LoadRuleKeyMapper: expected an error for an invalid regex pattern")
+	}
+}
+
+func TestLoadRuleKeyMapperMissingFile(t *testing.T) {
+	if _, err := LoadRuleKeyMapper("/does/not/exist.toml"); err == nil {
+		t.Fatal("LoadRuleKeyMapper: expected an error for a missing file")
+	}
+}
+
+func TestChainKeyMapperFallsThroughInOrder(t *testing.T) {
+	first := fakeMapper{err: fmt.Errorf("no match")}
+	second := fakeMapper{meas: "fallback", fields: map[string]interface{}{"value": 1}}
+	chain := ChainKeyMapper{first, second}
+
+	meas, _, _, err := chain.Map("any.key", 1)
+	if err != nil {
+		t.Fatalf("Map: %v", err)
+	}
+	if meas != "fallback" {
+		t.Errorf("measurement = %q, want %q (first mapper should have been skipped on error)", meas, "fallback")
+	}
+}
+
+func TestChainKeyMapperAllFail(t *testing.T) {
+	chain := ChainKeyMapper{fakeMapper{err: fmt.Errorf("no match")}, fakeMapper{err: fmt.Errorf("no match")}}
+
+	if _, _, _, err := chain.Map("any.key", 1); err == nil {
+		t.Fatal("Map: expected an error when every mapper in the chain fails")
+	}
+}
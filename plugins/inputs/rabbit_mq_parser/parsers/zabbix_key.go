@@ -0,0 +1,286 @@
+// zabbix_key.go is a hand-written recursive-descent parser for the
+// Zabbix item key grammar:
+//
+//	key   := IDENT ('.' IDENT)* ('[' param (',' param)* ']')?
+//	param := quoted | unquoted | key
+//
+// It is a left-to-right scan with a bracket-depth counter (no
+// strings.Split); a bracket parameter that itself contains brackets is
+// scanned again as a nested key. So arbitrarily nested brackets -
+// including a nested key as a bracket parameter - parse correctly instead of falling
+// through to a default case the way the legacy decision tree does.
+//
+// SCOPE NOTE, flagged for explicit maintainer sign-off: the request this
+// parser was built for asked for a Ragel-generated state machine (a
+// zabbix_key.go.rl grammar plus a `make zabbix_key.go` target invoking
+// `ragel -Z -G2`). An earlier commit shipped exactly that shape but
+// faked it - a "Code generated by ragel, DO NOT EDIT" header on this
+// hand-written file, plus a .rl grammar whose actions called a pb
+// builder type that was never implemented, so `make zabbix_key.go`
+// would neither reproduce this file nor compile. That .rl file and its
+// Makefile target have been deleted rather than re-faked. This sandbox
+// has no ragel binary to actually generate and verify a replacement
+// against, so rather than gamble on a second unverifiable codegen
+// artifact, this file stays what it provably is: a hand-written parser
+// satisfying the same grammar, with the test suite in
+// zabbix_key_test.go in place of the requested Ragel-vs-current
+// benchmark. Swapping in real Ragel codegen remains open pending a
+// toolchain that can generate and test it.
+
+package parsers
+
+import "fmt"
+
+// ParamKind discriminates the two shapes a Key's bracket parameter can
+// take: a plain string token, or a recursively nested Key (the case the
+// old strings.Split-based structureKey couldn't represent at all).
+type ParamKind int
+
+const (
+	// ParamString is a quoted or unquoted string token.
+	ParamString ParamKind = iota
+	// ParamKey is a parameter that is itself a full Zabbix item key,
+	// e.g. the second parameter of grpavg["app-x","system.cpu.util[,user]",last,0].
+	ParamKey
+)
+
+// Param is one bracketed parameter of a Key.
+type Param struct {
+	Kind ParamKind
+	Str  string // set when Kind == ParamString; quotes already stripped
+	Key  *Key   // set when Kind == ParamKey
+}
+
+// String renders p back to its source form.
+func (p Param) String() string {
+	if p.Kind == ParamKey {
+		return p.Key.String()
+	}
+	return p.Str
+}
+
+// Key is the parsed form of a Zabbix item key such as
+// "system.cpu.util[,user]" or
+// "grpavg[\"app-x\",\"system.cpu.util[,user]\",last,0]".
+type Key struct {
+	Namespace []string
+	Params    []Param
+}
+
+// String renders k back to its source form.
+func (k *Key) String() string {
+	s := ""
+	for i, part := range k.Namespace {
+		if i > 0 {
+			s += "."
+		}
+		s += part
+	}
+	if len(k.Params) == 0 {
+		return s
+	}
+	s += "["
+	for i, p := range k.Params {
+		if i > 0 {
+			s += ","
+		}
+		s += p.String()
+	}
+	s += "]"
+	return s
+}
+
+// ParseKey parses key per the grammar described at the top of this file:
+//
+//	key   := IDENT ('.' IDENT)* ('[' param (',' param)* ']')?
+//	param := quoted | unquoted | key
+//
+// Parsing is a left-to-right scan with a bracket-depth counter; a
+// parameter containing brackets is scanned again by a recursive ParseKey
+// call. So arbitrarily nested brackets (and nested keys within them)
+// parse correctly instead of falling through to a default case.
+func ParseKey(key string) (*Key, error) {
+	p := &keyParser{data: key}
+	k, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.data) {
+		return nil, fmt.Errorf("zabbix key: unexpected trailing input at %d in %q", p.pos, key)
+	}
+	return k, nil
+}
+
+type keyParser struct {
+	data string
+	pos  int
+}
+
+func (p *keyParser) parseKey() (*Key, error) {
+	k := &Key{}
+
+	ident, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	k.Namespace = append(k.Namespace, ident)
+
+	for p.peek() == '.' {
+		p.pos++
+		ident, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		k.Namespace = append(k.Namespace, ident)
+	}
+
+	if p.peek() != '[' {
+		return k, nil
+	}
+	p.pos++ // consume '['
+
+	for {
+		param, err := p.parseParam()
+		if err != nil {
+			return nil, err
+		}
+		k.Params = append(k.Params, param)
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+			continue
+		case ']':
+			p.pos++
+			return k, nil
+		default:
+			return nil, fmt.Errorf("zabbix key: expected ',' or ']' at %d in %q", p.pos, p.data)
+		}
+	}
+}
+
+func (p *keyParser) parseIdent() (string, error) {
+	start := p.pos
+	for p.pos < len(p.data) && isIdentByte(p.data[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("zabbix key: expected identifier at %d in %q", start, p.data)
+	}
+	return p.data[start:p.pos], nil
+}
+
+// parseParam parses one bracket parameter: a double-quoted string, a
+// nested key, or an unquoted token running up to the next ',' or ']' at
+// the current bracket depth. A quoted or unquoted param whose contents
+// themselves contain brackets - e.g. both forms of
+// "system.cpu.util[,user]" in
+// grpavg["app-x","system.cpu.util[,user]",last,0] - is reparsed as a
+// nested Key rather than kept as a plain string.
+func (p *keyParser) parseParam() (Param, error) {
+	var raw string
+	if p.peek() == '"' {
+		s, err := p.parseQuoted()
+		if err != nil {
+			return Param{}, err
+		}
+		raw = s
+	} else {
+		raw = p.parseUnquoted()
+	}
+
+	if containsBracket(raw) {
+		if nested, err := ParseKey(raw); err == nil {
+			return Param{Kind: ParamKey, Key: nested}, nil
+		}
+	}
+	return Param{Kind: ParamString, Str: raw}, nil
+}
+
+// parseUnquoted consumes an unquoted token up to the next ',' or ']' at
+// the current bracket depth, tracking nested '[' ']' pairs so a token
+// like "system.cpu.util[,user]" is consumed whole rather than stopping
+// at its inner ']'.
+func (p *keyParser) parseUnquoted() string {
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case '[':
+			depth++
+		case ']':
+			if depth == 0 {
+				return p.data[start:p.pos]
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				return p.data[start:p.pos]
+			}
+		}
+		p.pos++
+	}
+	return p.data[start:p.pos]
+}
+
+func containsBracket(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '[' {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *keyParser) parseQuoted() (string, error) {
+	p.pos++ // consume opening '"'
+	start := p.pos
+	var buf []byte
+	escaped := false
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if escaped {
+			buf = append(buf, c)
+			escaped = false
+			p.pos++
+			continue
+		}
+		switch c {
+		case '\\':
+			if buf == nil {
+				buf = append(buf, p.data[start:p.pos]...)
+			}
+			escaped = true
+			p.pos++
+		case '"':
+			p.pos++ // consume closing '"'
+			if buf == nil {
+				return p.data[start : p.pos-1], nil
+			}
+			return string(buf), nil
+		default:
+			if buf != nil {
+				buf = append(buf, c)
+			}
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("zabbix key: unterminated quoted string at %d in %q", start, p.data)
+}
+
+func (p *keyParser) peek() byte {
+	if p.pos >= len(p.data) {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+func isIdentByte(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	case c == '_' || c == '-':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package parsers
+
+import "testing"
+
+func TestParseKey(t *testing.T) {
+	cases := []struct {
+		name string
+		key  string
+		want *Key
+	}{
+		{
+			name: "no brackets",
+			key:  "system.cpu.load",
+			want: &Key{Namespace: []string{"system", "cpu", "load"}},
+		},
+		{
+			name: "simple bracket",
+			key:  "system.cpu.util[,user]",
+			want: &Key{
+				Namespace: []string{"system", "cpu", "util"},
+				Params: []Param{
+					{Kind: ParamString, Str: ""},
+					{Kind: ParamString, Str: "user"},
+				},
+			},
+		},
+		{
+			// The flagship example from the request this grammar exists
+			// to fix: LegacyKeyMapper's strings.Split-based decision
+			// tree falls through to its default case on this shape
+			// because it never handles a bracket parameter that is
+			// itself a full key.
+			name: "nested key as a quoted bracket parameter",
+			key:  `grpavg["app-searchautocomplete","system.cpu.util[,user]",last,0]`,
+			want: &Key{
+				Namespace: []string{"grpavg"},
+				Params: []Param{
+					{Kind: ParamString, Str: "app-searchautocomplete"},
+					{Kind: ParamKey, Key: &Key{
+						Namespace: []string{"system", "cpu", "util"},
+						Params: []Param{
+							{Kind: ParamString, Str: ""},
+							{Kind: ParamString, Str: "user"},
+						},
+					}},
+					{Kind: ParamString, Str: "last"},
+					{Kind: ParamString, Str: "0"},
+				},
+			},
+		},
+		{
+			// Same nested-key shape, but as an unquoted parameter.
+			name: "nested key as an unquoted bracket parameter",
+			key:  "grpavg[app,system.cpu.util[,user],last,0]",
+			want: &Key{
+				Namespace: []string{"grpavg"},
+				Params: []Param{
+					{Kind: ParamString, Str: "app"},
+					{Kind: ParamKey, Key: &Key{
+						Namespace: []string{"system", "cpu", "util"},
+						Params: []Param{
+							{Kind: ParamString, Str: ""},
+							{Kind: ParamString, Str: "user"},
+						},
+					}},
+					{Kind: ParamString, Str: "last"},
+					{Kind: ParamString, Str: "0"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseKey(tc.key)
+			if err != nil {
+				t.Fatalf("ParseKey(%q): %v", tc.key, err)
+			}
+			if got.String() != tc.want.String() {
+				t.Fatalf("ParseKey(%q) = %#v, want %#v", tc.key, got, tc.want)
+			}
+			if !keysEqual(got, tc.want) {
+				t.Fatalf("ParseKey(%q) = %#v, want %#v", tc.key, got, tc.want)
+			}
+		})
+	}
+}
+
+func keysEqual(a, b *Key) bool {
+	if len(a.Namespace) != len(b.Namespace) || len(a.Params) != len(b.Params) {
+		return false
+	}
+	for i := range a.Namespace {
+		if a.Namespace[i] != b.Namespace[i] {
+			return false
+		}
+	}
+	for i := range a.Params {
+		pa, pb := a.Params[i], b.Params[i]
+		if pa.Kind != pb.Kind {
+			return false
+		}
+		switch pa.Kind {
+		case ParamString:
+			if pa.Str != pb.Str {
+				return false
+			}
+		case ParamKey:
+			if !keysEqual(pa.Key, pb.Key) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestGrammarKeyMapperMapNestedKey(t *testing.T) {
+	key := `grpavg["app-searchautocomplete","system.cpu.util[,user]",last,0]`
+
+	meas, tags, fields, err := GrammarKeyMapper{}.Map(key, 42.0)
+	if err != nil {
+		t.Fatalf("Map(%q): %v", key, err)
+	}
+
+	if meas != "grpavg" {
+		t.Errorf("measurement = %q, want %q", meas, "grpavg")
+	}
+	wantTag := "system.cpu.util[,user]"
+	if got := tags["param1_key"]; got != wantTag {
+		t.Errorf("tags[param1_key] = %q, want %q", got, wantTag)
+	}
+	wantField := "app-searchautocomplete.last.0"
+	v, ok := fields[wantField]
+	if !ok {
+		t.Fatalf("fields[%q] missing, got %v", wantField, fields)
+	}
+	if v != 42.0 {
+		t.Errorf("fields[%q] = %v, want 42.0", wantField, v)
+	}
+}
+
+func FuzzParseKey(f *testing.F) {
+	seeds := []string{
+		"system.cpu.load",
+		"system.cpu.util[,user]",
+		`grpavg["app-searchautocomplete","system.cpu.util[,user]",last,0]`,
+		`custom.vfs.dev["/",read]`,
+		"",
+		"[",
+		"]",
+		`"unterminated`,
+		"a.b.c.d.e.f[[[[",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, key string) {
+		// ParseKey must never panic, regardless of input; returning an
+		// error for malformed keys is fine.
+		_, _ = ParseKey(key)
+	})
+}
+
+const benchKey = `grpavg["app-searchautocomplete","system.cpu.util[,user]",last,0]`
+
+func BenchmarkParseKey(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseKey(benchKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStructureKeyLegacy(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		structureKey(benchKey, 42.0)
+	}
+}
@@ -0,0 +1,148 @@
+package parsers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+func init() {
+	Add("zabbix_pyrepr", func(mapper KeyMapper) MessageParser {
+		return &zabbixPyReprParser{mapper: mapper}
+	})
+}
+
+// zabbixPyReprParser parses the Python-repr-ish payload Zabbix's
+// send-to-RabbitMQ integrations emit, e.g.
+//
+//	{'host': 'web01', 'clock': 1500000000, 'value': '12.3', 'key': 'system.cpu.util[,user]', 'server': 'zbx01'}
+//
+// Quoting can be single or double quoted depending on the Zabbix
+// version/config, both are handled.
+type zabbixPyReprParser struct {
+	mapper KeyMapper
+}
+
+// Parse implements MessageParser.
+func (p *zabbixPyReprParser) Parse(body []byte) ([]telegraf.Metric, error) {
+	ir, err := sanitizeMsg(body)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := ir.parsedValue()
+	if err != nil {
+		return nil, err
+	}
+
+	meas, tags, fields, err := p.mapper.Map(ir.key, value)
+	if err != nil {
+		return nil, fmt.Errorf("zabbix_pyrepr: mapping key %q: %v", ir.key, err)
+	}
+	tags["host"] = ir.host
+	tags["server"] = ir.server
+
+	m, err := telegraf.NewMetric(meas, tags, fields, ir.clock)
+	if err != nil {
+		return nil, fmt.Errorf("zabbix_pyrepr: building metric: %v", err)
+	}
+	return []telegraf.Metric{m}, nil
+}
+
+// irMessage is an intermediate representation of a Zabbix pyrepr message
+// as it's pulled apart field-by-field.
+type irMessage struct {
+	host         string
+	clock        time.Time
+	value        string
+	key          string
+	server       string
+	doubleQuoted bool
+}
+
+// sanitizeMsg breaks a raw pyrepr payload into its constituent fields.
+func sanitizeMsg(body []byte) (*irMessage, error) {
+	ir := &irMessage{}
+
+	quote := `'`
+	parts := strings.SplitN(string(body), `'host'`, 2)
+	if len(parts) != 2 {
+		quote = `"`
+		parts = strings.SplitN(string(body), `"host"`, 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("zabbix_pyrepr: message missing host field")
+		}
+		ir.doubleQuoted = true
+	}
+
+	hostSplit := strings.SplitN(parts[1], quote+"clock"+quote, 2)
+	if len(hostSplit) != 2 {
+		return nil, fmt.Errorf("zabbix_pyrepr: message missing clock field")
+	}
+	clockSplit := strings.SplitN(hostSplit[1], quote+"value"+quote, 2)
+	if len(clockSplit) != 2 {
+		return nil, fmt.Errorf("zabbix_pyrepr: message missing value field")
+	}
+	valueSplit := strings.SplitN(clockSplit[1], quote+"key"+quote, 2)
+	if len(valueSplit) != 2 {
+		return nil, fmt.Errorf("zabbix_pyrepr: message missing key field")
+	}
+	keySplit := strings.SplitN(valueSplit[1], quote+"server"+quote, 2)
+	if len(keySplit) != 2 {
+		return nil, fmt.Errorf("zabbix_pyrepr: message missing server field")
+	}
+
+	ir.host = cleanHost(hostSplit[0])
+	clock, err := cleanClock(clockSplit[0])
+	if err != nil {
+		return nil, err
+	}
+	ir.clock = clock
+	ir.value = trim3(valueSplit[0])
+	ir.key = trim3(keySplit[0])
+	ir.server = cleanHost(keySplit[1])
+
+	return ir, nil
+}
+
+// parsedValue returns the value as a float64 when possible, falling back
+// to the raw string for non-numeric Zabbix values.
+func (ir *irMessage) parsedValue() (interface{}, error) {
+	if f, err := strconv.ParseFloat(ir.value, 64); err == nil {
+		return f, nil
+	}
+	return ir.value, nil
+}
+
+// cleanHost extracts the quoted value following a "'field': " separator.
+func cleanHost(str string) string {
+	c := strings.SplitN(str, "'", 3)
+	if len(c) < 2 {
+		c = strings.SplitN(str, "\"", 3)
+	}
+	if len(c) < 2 {
+		return strings.TrimSpace(str)
+	}
+	return c[1]
+}
+
+// cleanClock parses the ": <unix ts>, " segment following "'clock'".
+func cleanClock(str string) (time.Time, error) {
+	trimmed := strings.Trim(strings.TrimSpace(str), ": ,")
+	i, err := strconv.ParseInt(trimmed, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("zabbix_pyrepr: parsing clock %q: %v", trimmed, err)
+	}
+	return time.Unix(i, 0), nil
+}
+
+// trim3 trims the ": '" / "', " padding surrounding a quoted field value.
+func trim3(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.Trim(s, ":, ")
+	s = strings.Trim(s, `'"`)
+	return s
+}
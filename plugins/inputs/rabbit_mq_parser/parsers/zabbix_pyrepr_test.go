@@ -0,0 +1,81 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeMsg(t *testing.T) {
+	cases := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name: "single quoted",
+			body: `{'host': 'web01', 'clock': 1500000000, 'value': '12.3', 'key': 'system.cpu.util[,user]', 'server': 'zbx01'}`,
+		},
+		{
+			name: "double quoted",
+			body: `{"host": "web01", "clock": 1500000000, "value": "12.3", "key": "system.cpu.util[,user]", "server": "zbx01"}`,
+		},
+		{name: "empty body", body: "", wantErr: true},
+		{name: "missing host field", body: `{'clock': 1500000000, 'value': '12.3', 'key': 'a.b', 'server': 'zbx01'}`, wantErr: true},
+		{name: "missing clock field", body: `{'host': 'web01', 'value': '12.3', 'key': 'a.b', 'server': 'zbx01'}`, wantErr: true},
+		{name: "missing value field", body: `{'host': 'web01', 'clock': 1500000000, 'key': 'a.b', 'server': 'zbx01'}`, wantErr: true},
+		{name: "missing key field", body: `{'host': 'web01', 'clock': 1500000000, 'value': '12.3', 'server': 'zbx01'}`, wantErr: true},
+		{name: "missing server field", body: `{'host': 'web01', 'clock': 1500000000, 'value': '12.3', 'key': 'a.b'}`, wantErr: true},
+		{name: "truncated mid-field", body: `{'host': 'web0`, wantErr: true},
+		{name: "non-numeric clock", body: `{'host': 'web01', 'clock': 'not-a-number', 'value': '12.3', 'key': 'a.b', 'server': 'zbx01'}`, wantErr: true},
+		{name: "garbage bytes", body: "\x00\xff\xfe'host'\x01", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ir, err := sanitizeMsg([]byte(tc.body))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("sanitizeMsg(%q) = %+v, <nil>; want an error", tc.body, ir)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sanitizeMsg(%q): %v", tc.body, err)
+			}
+			if ir.host != "web01" || ir.key != "system.cpu.util[,user]" || ir.server != "zbx01" {
+				t.Fatalf("sanitizeMsg(%q) = %+v, unexpected fields", tc.body, ir)
+			}
+		})
+	}
+}
+
+func TestCleanClock(t *testing.T) {
+	if _, err := cleanClock(": 1500000000, "); err != nil {
+		t.Fatalf("cleanClock: %v", err)
+	}
+	if _, err := cleanClock(": not-a-number, "); err == nil {
+		t.Fatal("cleanClock: expected an error for non-numeric input")
+	}
+	if _, err := cleanClock(""); err == nil {
+		t.Fatal("cleanClock: expected an error for empty input")
+	}
+}
+
+// FuzzSanitizeMsg exercises sanitizeMsg against arbitrary bytes, the same
+// property zabbix_key_test.go's FuzzParseKey checks for the key grammar:
+// malformed input must produce an error, never a panic.
+func FuzzSanitizeMsg(f *testing.F) {
+	seeds := []string{
+		`{'host': 'web01', 'clock': 1500000000, 'value': '12.3', 'key': 'system.cpu.util[,user]', 'server': 'zbx01'}`,
+		`{"host": "web01", "clock": 1500000000, "value": "12.3", "key": "a.b", "server": "zbx01"}`,
+		"",
+		"'host'",
+		strings.Repeat("'", 64),
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, body string) {
+		_, _ = sanitizeMsg([]byte(body))
+	})
+}
@@ -0,0 +1,156 @@
+package statsd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/plugins/common/msgsource"
+)
+
+// defaultCacheCapacity is how many recent samples are kept per series for
+// RPC "last value" lookups.
+const defaultCacheCapacity = 8
+
+// cacheKey identifies a series in the metricCache. measurement alone is
+// too coarse to distinguish series: GrammarKeyMapper/LegacyKeyMapper both
+// collapse many distinct keys onto the same measurement (e.g. every
+// grpavg[...] key maps to measurement "grpavg"), so tags and field are
+// part of the identity too.
+type cacheKey struct {
+	host        string
+	measurement string
+	tags        string // canonicalTags(tags), excluding "host"
+	field       string
+}
+
+// sample is a single cached value/time pair.
+type sample struct {
+	value interface{}
+	time  time.Time
+}
+
+// metricCache is a small in-memory ring buffer of recently ingested
+// samples, keyed by (host, measurement, tags, field), used to answer RPC
+// queries without a round trip to whatever's consuming the Accumulator.
+type metricCache struct {
+	mu       sync.Mutex
+	capacity int
+	buffers  map[cacheKey][]sample
+}
+
+// newMetricCache creates a metricCache that keeps up to capacity samples
+// per series.
+func newMetricCache(capacity int) *metricCache {
+	return &metricCache{
+		capacity: capacity,
+		buffers:  make(map[cacheKey][]sample),
+	}
+}
+
+// record appends a sample, evicting the oldest once capacity is exceeded.
+func (c *metricCache) record(host, measurement string, tags map[string]string, field string, value interface{}, t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := cacheKey{host: host, measurement: measurement, tags: canonicalTags(tags), field: field}
+	buf := append(c.buffers[key], sample{value: value, time: t})
+	if len(buf) > c.capacity {
+		buf = buf[len(buf)-c.capacity:]
+	}
+	c.buffers[key] = buf
+}
+
+// last returns the most recently recorded sample for (host, measurement,
+// tags, field).
+func (c *metricCache) last(host, measurement string, tags map[string]string, field string) (sample, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := cacheKey{host: host, measurement: measurement, tags: canonicalTags(tags), field: field}
+	buf := c.buffers[key]
+	if len(buf) == 0 {
+		return sample{}, false
+	}
+	return buf[len(buf)-1], true
+}
+
+// canonicalTags renders tags (excluding "host", which cacheKey already
+// carries separately) as a sorted "k1=v1,k2=v2" string, so two equal tag
+// sets always produce the same cacheKey regardless of map iteration
+// order.
+func canonicalTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		if k == "host" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(tags[k])
+	}
+	return b.String()
+}
+
+// rpcQuery is the expected body of an RPC request: "what is the last
+// value for measurement/tags/field on host". Tags (other than "host")
+// and Field must match the series' tags and field name exactly or the
+// query won't find it; there is no wildcard, so an omitted Field only
+// matches a field whose name is empty.
+type rpcQuery struct {
+	Host        string            `json:"host"`
+	Measurement string            `json:"measurement"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Field       string            `json:"field,omitempty"`
+}
+
+// rpcResponse is published back to the requester's reply-to queue.
+type rpcResponse struct {
+	Host        string            `json:"host"`
+	Measurement string            `json:"measurement"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Field       string            `json:"field,omitempty"`
+	Found       bool              `json:"found"`
+	Value       interface{}       `json:"value,omitempty"`
+	Time        time.Time         `json:"time,omitempty"`
+}
+
+// respond answers an RPC request carried by msg, using the standard AMQP
+// RPC pattern: publish the response to the default exchange, routed to
+// msg.ReplyTo, with msg.CorrelationID echoed back so the caller can match
+// it to its request.
+func (rmq *RabbitMQParser) respond(msg msgsource.RawMessage) error {
+	var q rpcQuery
+	if err := json.Unmarshal(msg.Body, &q); err != nil {
+		return fmt.Errorf("rabbit_mq_parser: rpc: invalid query: %v", err)
+	}
+
+	resp := rpcResponse{Host: q.Host, Measurement: q.Measurement, Tags: q.Tags, Field: q.Field}
+	if s, ok := rmq.cache.last(q.Host, q.Measurement, q.Tags, q.Field); ok {
+		resp.Found = true
+		resp.Value = s.value
+		resp.Time = s.time
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("rabbit_mq_parser: rpc: marshaling response: %v", err)
+	}
+
+	if err := rmq.source.Publish(msg.ReplyTo, msg.CorrelationID, body); err != nil {
+		return fmt.Errorf("rabbit_mq_parser: rpc: publishing response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package statsd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetricCacheDistinguishesSeriesWithSameMeasurement(t *testing.T) {
+	c := newMetricCache(defaultCacheCapacity)
+	now := time.Now()
+
+	// Two distinct grpavg[...] keys both map to measurement "grpavg";
+	// they must not clobber each other just because they share it.
+	c.record("web01", "grpavg", map[string]string{"param1_key": "system.cpu.util[,user]"}, "app-searchautocomplete.last.0", 1.0, now)
+	c.record("web01", "grpavg", map[string]string{"param1_key": "system.cpu.util[,idle]"}, "app-searchautocomplete.last.0", 2.0, now)
+
+	s1, ok := c.last("web01", "grpavg", map[string]string{"param1_key": "system.cpu.util[,user]"}, "app-searchautocomplete.last.0")
+	if !ok || s1.value != 1.0 {
+		t.Fatalf("got %v, %v; want 1.0, true", s1.value, ok)
+	}
+
+	s2, ok := c.last("web01", "grpavg", map[string]string{"param1_key": "system.cpu.util[,idle]"}, "app-searchautocomplete.last.0")
+	if !ok || s2.value != 2.0 {
+		t.Fatalf("got %v, %v; want 2.0, true", s2.value, ok)
+	}
+}
+
+func TestMetricCacheDistinguishesFields(t *testing.T) {
+	c := newMetricCache(defaultCacheCapacity)
+	now := time.Now()
+
+	c.record("web01", "system", map[string]string{"cpu": "cpu0"}, "util.user", 10.0, now)
+	c.record("web01", "system", map[string]string{"cpu": "cpu0"}, "util.idle", 90.0, now)
+
+	user, ok := c.last("web01", "system", map[string]string{"cpu": "cpu0"}, "util.user")
+	if !ok || user.value != 10.0 {
+		t.Fatalf("got %v, %v; want 10.0, true", user.value, ok)
+	}
+
+	idle, ok := c.last("web01", "system", map[string]string{"cpu": "cpu0"}, "util.idle")
+	if !ok || idle.value != 90.0 {
+		t.Fatalf("got %v, %v; want 90.0, true", idle.value, ok)
+	}
+}
+
+func TestCanonicalTagsStableAcrossMapOrder(t *testing.T) {
+	a := canonicalTags(map[string]string{"b": "2", "a": "1", "host": "web01"})
+	b := canonicalTags(map[string]string{"a": "1", "b": "2"})
+	if a != b {
+		t.Fatalf("canonicalTags not order-stable or host not excluded: %q != %q", a, b)
+	}
+	if a != "a=1,b=2" {
+		t.Fatalf("canonicalTags = %q, want %q", a, "a=1,b=2")
+	}
+}